@@ -1,6 +1,9 @@
 package config
 
 import (
+	"strings"
+	"time"
+
 	nested "github.com/antonfisher/nested-logrus-formatter"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -10,12 +13,85 @@ type Config struct {
 	ApplicationNamespace string `mapstructure:"APP_APPLICATION_NAMESPACE"`
 	LogLevel             string `mapstructure:"APP_LOG_LEVEL"`
 	LogFormat            string `mapstructure:"APP_LOG_FORMAT"`
+
+	// ApplicationLabelSelector ограничивает реконсиляцию Application-ресурсами,
+	// совпадающими с данным селектором (синтаксис k8s.io/apimachinery/pkg/labels).
+	// Пустая строка означает отсутствие ограничений.
+	ApplicationLabelSelector string `mapstructure:"APP_APPLICATION_LABEL_SELECTOR"`
+
+	// WatchNamespaces — список namespace, в которых менеджер следит за ресурсами.
+	// Пустой список означает отслеживание всех namespace кластера.
+	WatchNamespaces []string
+
+	// WebhookCertDir — директория с TLS-сертификатом webhook-сервера.
+	WebhookCertDir string `mapstructure:"APP_WEBHOOK_CERT_DIR"`
+
+	// DefaultProject подставляется defaulting-вебхуком, если Spec.Project не задан.
+	DefaultProject string `mapstructure:"APP_DEFAULT_PROJECT"`
+
+	// AllowedRepoURLs, AllowedProjects и AllowedDestinationClusters — списки,
+	// разрешённые для Application валидирующим вебхуком. Пустой список снимает
+	// ограничение по соответствующему полю.
+	AllowedRepoURLs            []string
+	AllowedProjects            []string
+	AllowedDestinationClusters []string
+
+	// RequiredLabels — метки, обязательные для каждого Application согласно
+	// валидирующему вебхуку.
+	RequiredLabels []string
+
+	// MetricsAddr — адрес, на котором manager публикует /metrics.
+	MetricsAddr string `mapstructure:"APP_METRICS_ADDR"`
+
+	// ProbeAddr — адрес, на котором manager публикует /healthz и /readyz.
+	ProbeAddr string `mapstructure:"APP_PROBE_ADDR"`
+
+	// TransformConfigPath — путь к YAML-файлу с описанием конвейера
+	// transform.Transformer. Пустое значение отключает конвейер (тождественное
+	// копирование, как раньше).
+	TransformConfigPath string `mapstructure:"APP_TRANSFORM_CONFIG"`
+
+	// LeaderElect включает leader election, позволяя запускать несколько реплик.
+	LeaderElect bool `mapstructure:"APP_LEADER_ELECT"`
+
+	// LeaderElectionNamespace и LeaderElectionID определяют Lease, за который
+	// реплики конкурируют при включённом LeaderElect.
+	LeaderElectionNamespace string `mapstructure:"APP_LEADER_ELECTION_NAMESPACE"`
+	LeaderElectionID        string `mapstructure:"APP_LEADER_ELECTION_ID"`
+
+	// LeaseDuration, RenewDeadline и RetryPeriod настраивают Lease leader election.
+	LeaseDuration time.Duration `mapstructure:"APP_LEASE_DURATION"`
+	RenewDeadline time.Duration `mapstructure:"APP_RENEW_DEADLINE"`
+	RetryPeriod   time.Duration `mapstructure:"APP_RETRY_PERIOD"`
+
+	// ShutdownGracePeriod — время, в течение которого manager дожидается
+	// завершения реконсиляций после отмены контекста по SIGTERM/SIGINT, прежде
+	// чем вернуть управление.
+	ShutdownGracePeriod time.Duration `mapstructure:"APP_SHUTDOWN_GRACE_PERIOD"`
 }
 
 const (
-	applicationNamespaceKey = "APP_APPLICATION_NAMESPACE"
-	logLevelKey             = "APP_LOG_LEVEL"
-	logFormatKey            = "APP_LOG_FORMAT"
+	applicationNamespaceKey     = "APP_APPLICATION_NAMESPACE"
+	logLevelKey                 = "APP_LOG_LEVEL"
+	logFormatKey                = "APP_LOG_FORMAT"
+	applicationLabelSelectorKey = "APP_APPLICATION_LABEL_SELECTOR"
+	watchNamespacesKey          = "APP_WATCH_NAMESPACES"
+	webhookCertDirKey           = "APP_WEBHOOK_CERT_DIR"
+	defaultProjectKey           = "APP_DEFAULT_PROJECT"
+	allowedRepoURLsKey          = "APP_ALLOWED_REPO_URLS"
+	allowedProjectsKey          = "APP_ALLOWED_PROJECTS"
+	allowedDestClustersKey      = "APP_ALLOWED_DESTINATION_CLUSTERS"
+	requiredLabelsKey           = "APP_REQUIRED_LABELS"
+	metricsAddrKey              = "APP_METRICS_ADDR"
+	probeAddrKey                = "APP_PROBE_ADDR"
+	transformConfigPathKey      = "APP_TRANSFORM_CONFIG"
+	leaderElectKey              = "APP_LEADER_ELECT"
+	leaderElectionNamespaceKey  = "APP_LEADER_ELECTION_NAMESPACE"
+	leaderElectionIDKey         = "APP_LEADER_ELECTION_ID"
+	leaseDurationKey            = "APP_LEASE_DURATION"
+	renewDeadlineKey            = "APP_RENEW_DEADLINE"
+	retryPeriodKey              = "APP_RETRY_PERIOD"
+	shutdownGracePeriodKey      = "APP_SHUTDOWN_GRACE_PERIOD"
 )
 
 var (
@@ -30,16 +106,65 @@ func loadConfig() *Config {
 	viper.SetDefault(applicationNamespaceKey, "argocd")
 	viper.SetDefault(logLevelKey, "info")
 	viper.SetDefault(logFormatKey, "nested")
+	viper.SetDefault(applicationLabelSelectorKey, "")
+	viper.SetDefault(watchNamespacesKey, "")
+	viper.SetDefault(webhookCertDirKey, "/tmp/k8s-webhook-server/serving-certs")
+	viper.SetDefault(defaultProjectKey, "default")
+	viper.SetDefault(allowedRepoURLsKey, "")
+	viper.SetDefault(allowedProjectsKey, "")
+	viper.SetDefault(allowedDestClustersKey, "")
+	viper.SetDefault(requiredLabelsKey, "")
+	viper.SetDefault(metricsAddrKey, ":8080")
+	viper.SetDefault(probeAddrKey, ":8081")
+	viper.SetDefault(transformConfigPathKey, "")
+	viper.SetDefault(leaderElectKey, false)
+	viper.SetDefault(leaderElectionNamespaceKey, "argocd")
+	viper.SetDefault(leaderElectionIDKey, "argocdsyncer-leader")
+	viper.SetDefault(leaseDurationKey, 15*time.Second)
+	viper.SetDefault(renewDeadlineKey, 10*time.Second)
+	viper.SetDefault(retryPeriodKey, 2*time.Second)
+	viper.SetDefault(shutdownGracePeriodKey, 30*time.Second)
 
 	conf.ApplicationNamespace = viper.GetString(applicationNamespaceKey)
 	conf.LogLevel = viper.GetString(logLevelKey)
 	conf.LogFormat = viper.GetString(logFormatKey)
+	conf.ApplicationLabelSelector = viper.GetString(applicationLabelSelectorKey)
+	conf.WatchNamespaces = parseCommaList(viper.GetString(watchNamespacesKey))
+	conf.WebhookCertDir = viper.GetString(webhookCertDirKey)
+	conf.DefaultProject = viper.GetString(defaultProjectKey)
+	conf.AllowedRepoURLs = parseCommaList(viper.GetString(allowedRepoURLsKey))
+	conf.AllowedProjects = parseCommaList(viper.GetString(allowedProjectsKey))
+	conf.AllowedDestinationClusters = parseCommaList(viper.GetString(allowedDestClustersKey))
+	conf.RequiredLabels = parseCommaList(viper.GetString(requiredLabelsKey))
+	conf.MetricsAddr = viper.GetString(metricsAddrKey)
+	conf.ProbeAddr = viper.GetString(probeAddrKey)
+	conf.TransformConfigPath = viper.GetString(transformConfigPathKey)
+	conf.LeaderElect = viper.GetBool(leaderElectKey)
+	conf.LeaderElectionNamespace = viper.GetString(leaderElectionNamespaceKey)
+	conf.LeaderElectionID = viper.GetString(leaderElectionIDKey)
+	conf.LeaseDuration = viper.GetDuration(leaseDurationKey)
+	conf.RenewDeadline = viper.GetDuration(renewDeadlineKey)
+	conf.RetryPeriod = viper.GetDuration(retryPeriodKey)
+	conf.ShutdownGracePeriod = viper.GetDuration(shutdownGracePeriodKey)
 
 	conf.initLogger()
 
 	return &conf
 }
 
+// parseCommaList разбирает список namespace через запятую в срез строк,
+// отбрасывая пустые элементы.
+func parseCommaList(raw string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(raw, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
 func (c *Config) initLogger() {
 	const defaultLogLevel = "info"
 	const defaultLogFormat = "nested"
@@ -19,18 +19,23 @@ package controllers
 import (
 	"context"
 	"errors"
-	"reflect"
+	"fmt"
+	"time"
 
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/sirupsen/logrus"
 	"github.ru/wbe7/argocdsyncer/config"
+	"github.ru/wbe7/argocdsyncer/metrics"
+	"github.ru/wbe7/argocdsyncer/transform"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 var (
@@ -44,6 +49,10 @@ type ApplicationReconciler struct {
 	client.Client
 	log    *logrus.Entry
 	Scheme *runtime.Scheme
+
+	// Transformers — конвейер, выводящий целевой Application из исходного.
+	// Пустой конвейер сохраняет прежнее поведение тождественного копирования.
+	Transformers transform.Pipeline
 }
 
 //+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;list;watch;create;update;patch;delete
@@ -61,6 +70,11 @@ type ApplicationReconciler struct {
 func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var log = r.log.WithField("application", req.NamespacedName)
 
+	start := time.Now()
+	defer func() {
+		metrics.ReconcileDuration.WithLabelValues(req.Namespace).Observe(time.Since(start).Seconds())
+	}()
+
 	// Получение данных ресурса из k8s
 	var desiredResource appv1.Application
 	var err = r.Get(ctx, req.NamespacedName, &desiredResource)
@@ -70,6 +84,7 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			return ctrl.Result{}, nil
 		}
 		log.Errorf("< Ошибка при чтении CR Application: %v", err)
+		metrics.ApplicationsFailed.WithLabelValues(req.Namespace, "get").Inc()
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -87,6 +102,7 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		err = r.processDefaultFinalization(log, ctx, &desiredResource, r.finalize)
 		if err != nil {
 			log.Errorf("Ошибка при финализации ресурса %v: %v", desiredResource.GetName(), err)
+			metrics.ApplicationsFailed.WithLabelValues(req.Namespace, "finalize").Inc()
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 		return ctrl.Result{}, nil
@@ -97,6 +113,7 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		err = r.InjectDefaultFinalizer(ctx, &desiredResource)
 		if err != nil {
 			log.Errorf("Ошибка при добавлении финализатора в ресурс %v: %v", desiredResource.GetName(), err)
+			metrics.ApplicationsFailed.WithLabelValues(req.Namespace, "inject-finalizer").Inc()
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 		return ctrl.Result{}, nil
@@ -106,15 +123,19 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	err = r.validate(&desiredResource)
 	if err != nil {
 		log.Errorf("Ошибка при валидации ресурса %v: %v", desiredResource.GetName(), err)
+		metrics.ApplicationsFailed.WithLabelValues(req.Namespace, "validate").Inc()
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	err = r.createOrUpdateApplication(ctx, &desiredResource)
 	if err != nil {
 		log.Errorf("Ошибка при реконсиляции Application %v: %v", desiredResource.GetName(), err)
+		metrics.ApplicationsFailed.WithLabelValues(req.Namespace, "create-or-update").Inc()
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	metrics.ApplicationsSynced.WithLabelValues(req.Namespace).Inc()
+
 	//Выход из цикла
 	return ctrl.Result{}, nil
 }
@@ -122,8 +143,17 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 // SetupWithManager sets up the controller with the Manager.
 func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.log = logrus.WithField("controller", "application")
+
+	selector, err := labels.Parse(config.EnvConfig.ApplicationLabelSelector)
+	if err != nil {
+		return fmt.Errorf("не удалось разобрать селектор меток %q: %w", config.EnvConfig.ApplicationLabelSelector, err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appv1.Application{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return selector.Matches(labels.Set(obj.GetLabels()))
+		})).
 		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
 		// For().
 		Complete(r)
@@ -179,7 +209,7 @@ func (r *ApplicationReconciler) processDefaultFinalization(
 
 func (r *ApplicationReconciler) finalize(ctx context.Context, resource *appv1.Application) error {
 	r.log.Infof("Удаляю целевой Application [%v.%v]", resource.GetName(), resource.GetNamespace())
-	desiredApplication, err := generateApplication(resource, appNamespace)
+	desiredApplication, err := r.generateApplication(resource, appNamespace)
 	if err != nil {
 		return err
 	}
@@ -187,6 +217,7 @@ func (r *ApplicationReconciler) finalize(ctx context.Context, resource *appv1.Ap
 	if err != nil {
 		return err
 	}
+	metrics.ApplicationsDeleted.WithLabelValues(appNamespace).Inc()
 	r.log.Infof("Успешно удален целевой Application [%v.%v]", resource.GetName(), resource.GetNamespace())
 	return nil
 }
@@ -200,7 +231,7 @@ func (r *ApplicationReconciler) validate(resource *appv1.Application) error {
 }
 
 func (r *ApplicationReconciler) createOrUpdateApplication(ctx context.Context, resource *appv1.Application) error {
-	desiredApplication, err := generateApplication(resource, appNamespace)
+	desiredApplication, err := r.generateApplication(resource, appNamespace)
 	if err != nil {
 		return err
 	}
@@ -209,39 +240,54 @@ func (r *ApplicationReconciler) createOrUpdateApplication(ctx context.Context, r
 		controllerutil.AddFinalizer(desiredApplication, argoFinalizer)
 	}
 
-	//
 	app := &appv1.Application{}
-
 	err = r.Get(ctx, types.NamespacedName{Name: desiredApplication.Name, Namespace: desiredApplication.Namespace}, app)
 
 	if err != nil && kerrors.IsNotFound(err) {
 		r.log.Infof("Целевой Application [%v.%v] не создан, создаю...", desiredApplication.GetName(), desiredApplication.GetNamespace())
-		err = r.Create(ctx, desiredApplication)
-		if err != nil {
+		if err := setLastAppliedAnnotation(desiredApplication); err != nil {
 			return err
 		}
-		r.log.Infof("Целевой Application [%v.%v] успешно создан", desiredApplication.GetName(), desiredApplication.GetNamespace())
-	} else {
-		//Если спецификация отличается, то обновляем
-		//TODO: Если финализатор добавляется после создания, то обновления не произойдет
-		//Для добавления Argo финализатора нужно пересоздать ресурс
-		//Для удаления Argo финализатора вмешательство администраторов
-		if !reflect.DeepEqual(app.Spec, desiredApplication.Spec) {
-			desiredApplication.ResourceVersion = app.ResourceVersion
-			r.log.Infof("Целевой Application [%v.%v] уже создан, обновляю...", desiredApplication.GetName(), desiredApplication.GetNamespace())
-			err = r.Update(ctx, desiredApplication)
-			if err != nil {
-				return err
-			}
-			r.log.Infof("Целевой Application [%v.%v] успешно обновлен", desiredApplication.GetName(), desiredApplication.GetNamespace())
+		if err := r.applyApplication(ctx, nil, desiredApplication); err != nil {
+			return err
 		}
+		metrics.ApplicationsCreated.WithLabelValues(desiredApplication.Namespace).Inc()
+		r.log.Infof("Целевой Application [%v.%v] успешно создан", desiredApplication.GetName(), desiredApplication.GetNamespace())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Дрейф, внесённый сторонними контроллерами (например, финализатор, который
+	// Argo добавляет в целевой Application уже после его создания), выявляется
+	// сравнением живого ресурса с желаемым по spec, меткам и финализаторам;
+	// обновление выполняется патчем (server-side apply либо three-way merge
+	// patch по аннотации last-applied), а не через Update всего объекта целиком.
+	if !applicationNeedsUpdate(app, desiredApplication) {
+		return nil
 	}
 
+	r.log.Infof("Целевой Application [%v.%v] уже создан, обновляю...", desiredApplication.GetName(), desiredApplication.GetNamespace())
+	if err := setLastAppliedAnnotation(desiredApplication); err != nil {
+		return err
+	}
+	if err := r.applyApplication(ctx, app, desiredApplication); err != nil {
+		return err
+	}
+	metrics.ApplicationsUpdated.WithLabelValues(desiredApplication.Namespace).Inc()
+	r.log.Infof("Целевой Application [%v.%v] успешно обновлен", desiredApplication.GetName(), desiredApplication.GetNamespace())
+
 	return nil
 }
 
-func generateApplication(resource *appv1.Application, namespace string) (*appv1.Application, error) {
-	return &appv1.Application{
+// generateApplication выводит целевой Application из исходного resource:
+// сперва строится тождественная копия, а затем, если задан конвейер
+// r.Transformers, она прогоняется через него (с namespace источника) для
+// переноса имени, меток, аннотаций и Spec согласно конфигурации. Результату
+// всегда назначается целевой namespace.
+func (r *ApplicationReconciler) generateApplication(resource *appv1.Application, namespace string) (*appv1.Application, error) {
+	base := &appv1.Application{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: resource.TypeMeta.APIVersion,
 			Kind:       resource.Kind,
@@ -253,5 +299,23 @@ func generateApplication(resource *appv1.Application, namespace string) (*appv1.
 			Annotations: resource.Annotations,
 		},
 		Spec: resource.Spec,
-	}, nil
+	}
+
+	if len(r.Transformers) == 0 {
+		return base, nil
+	}
+
+	// Трансформеры получают namespace источника (например, для "{namespace}"
+	// в namePrefixSuffix и карты projectRemap).
+	base.Namespace = resource.Namespace
+
+	target, err := r.Transformers.Run(base)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось применить конвейер трансформаций к Application %v: %w", resource.GetName(), err)
+	}
+
+	// Целевой namespace управляется реконсилером и не должен меняться трансформерами.
+	target.Namespace = namespace
+
+	return target, nil
 }
@@ -0,0 +1,168 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// lastAppliedAnnotation хранит JSON целевого Application таким, каким он был
+	// применён в прошлый раз, чтобы строить three-way merge patch для кластеров
+	// без поддержки server-side apply.
+	lastAppliedAnnotation = "argocdsyncer.wbe7.ru/last-applied"
+
+	// fieldOwner идентифицирует владельца полей при server-side apply.
+	fieldOwner = "argocdsyncer"
+)
+
+// applyApplication применяет desired к целевому Application через server-side
+// apply; при отказе кластера от SSA (например, старый API-сервер) выполняет
+// three-way JSON merge patch на основе аннотации lastAppliedAnnotation, либо,
+// если ресурса ещё не существует, создаёт его напрямую — PATCH не может
+// создать отсутствующий объект.
+func (r *ApplicationReconciler) applyApplication(ctx context.Context, live, desired *appv1.Application) error {
+	err := r.Patch(ctx, desired.DeepCopy(), client.Apply, client.ForceOwnership, client.FieldOwner(fieldOwner))
+	if err == nil {
+		return nil
+	}
+
+	if live == nil {
+		r.log.Warnf(
+			"Server-side apply недоступен для Application [%v.%v], создаю напрямую: %v",
+			desired.GetName(), desired.GetNamespace(), err,
+		)
+		return r.Create(ctx, desired.DeepCopy())
+	}
+
+	r.log.Warnf(
+		"Server-side apply недоступен для Application [%v.%v], использую merge patch: %v",
+		desired.GetName(), desired.GetNamespace(), err,
+	)
+
+	patch, patchErr := buildMergePatch(live, desired)
+	if patchErr != nil {
+		return patchErr
+	}
+
+	return r.Patch(ctx, live, client.RawPatch(types.MergePatchType, patch))
+}
+
+// applicationNeedsUpdate определяет, расходится ли желаемое состояние с живым
+// ресурсом по спецификации, меткам или финализаторам.
+func applicationNeedsUpdate(live, desired *appv1.Application) bool {
+	if !reflect.DeepEqual(live.Spec, desired.Spec) {
+		return true
+	}
+	if !reflect.DeepEqual(live.Labels, desired.Labels) {
+		return true
+	}
+	if !finalizersEqual(live.Finalizers, desired.Finalizers) {
+		return true
+	}
+	return false
+}
+
+func finalizersEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	aSet := make(map[string]struct{}, len(a))
+	for _, f := range a {
+		aSet[f] = struct{}{}
+	}
+	for _, f := range b {
+		if _, ok := aSet[f]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// setLastAppliedAnnotation сериализует resource (без учёта уже имеющейся
+// аннотации lastAppliedAnnotation) и сохраняет результат в ней же.
+func setLastAppliedAnnotation(resource *appv1.Application) error {
+	clean := resource.DeepCopy()
+	delete(clean.Annotations, lastAppliedAnnotation)
+
+	payload, err := json.Marshal(clean)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать Application для аннотации %v: %w", lastAppliedAnnotation, err)
+	}
+
+	if resource.Annotations == nil {
+		resource.Annotations = map[string]string{}
+	}
+	resource.Annotations[lastAppliedAnnotation] = string(payload)
+
+	return nil
+}
+
+// lastAppliedApplication восстанавливает последнее применённое состояние
+// ресурса из его аннотации. Возвращает nil, если аннотация отсутствует.
+func lastAppliedApplication(live *appv1.Application) (*appv1.Application, error) {
+	raw, ok := live.Annotations[lastAppliedAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var lastApplied appv1.Application
+	if err := json.Unmarshal([]byte(raw), &lastApplied); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать аннотацию %v ресурса %v: %w", lastAppliedAnnotation, live.GetName(), err)
+	}
+
+	return &lastApplied, nil
+}
+
+// buildMergePatch строит three-way JSON merge patch между последним применённым
+// состоянием (хранится в аннотации live), желаемым состоянием и текущим live.
+// Если аннотация ещё не была проставлена, в качестве original используется
+// пустой Application — патч тогда вырождается в обычный merge с desired.
+func buildMergePatch(live, desired *appv1.Application) ([]byte, error) {
+	lastApplied, err := lastAppliedApplication(live)
+	if err != nil {
+		return nil, err
+	}
+	if lastApplied == nil {
+		lastApplied = &appv1.Application{}
+	}
+
+	originalJSON, err := json.Marshal(lastApplied)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось сериализовать last-applied ресурса %v: %w", live.GetName(), err)
+	}
+
+	modifiedJSON, err := json.Marshal(desired)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось сериализовать желаемое состояние ресурса %v: %w", live.GetName(), err)
+	}
+
+	currentJSON, err := json.Marshal(live)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось сериализовать текущее состояние ресурса %v: %w", live.GetName(), err)
+	}
+
+	return jsonmergepatch.CreateThreeWayJSONMergePatch(originalJSON, modifiedJSON, currentJSON)
+}
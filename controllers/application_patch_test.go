@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestApplication() *appv1.Application {
+	return &appv1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "app",
+			Namespace:  "argocd",
+			Labels:     map[string]string{"team": "a"},
+			Finalizers: []string{defaultFinalizer},
+		},
+		Spec: appv1.ApplicationSpec{
+			Project: "default",
+		},
+	}
+}
+
+func TestApplicationNeedsUpdate_NoDiff(t *testing.T) {
+	live := newTestApplication()
+	desired := newTestApplication()
+
+	if applicationNeedsUpdate(live, desired) {
+		t.Fatal("ожидалось отсутствие необходимости обновления при идентичных ресурсах")
+	}
+}
+
+func TestApplicationNeedsUpdate_FinalizerOnlyDiff(t *testing.T) {
+	live := newTestApplication()
+	desired := newTestApplication()
+	desired.Finalizers = append(desired.Finalizers, argoFinalizer)
+
+	if !applicationNeedsUpdate(live, desired) {
+		t.Fatal("ожидалась необходимость обновления при расхождении только в финализаторах")
+	}
+}
+
+func TestApplicationNeedsUpdate_LabelOnlyDiff(t *testing.T) {
+	live := newTestApplication()
+	desired := newTestApplication()
+	desired.Labels["team"] = "b"
+
+	if !applicationNeedsUpdate(live, desired) {
+		t.Fatal("ожидалась необходимость обновления при расхождении только в метках")
+	}
+}
+
+func TestApplicationNeedsUpdate_SpecDiff(t *testing.T) {
+	live := newTestApplication()
+	desired := newTestApplication()
+	desired.Spec.Project = "tenant-x"
+
+	if !applicationNeedsUpdate(live, desired) {
+		t.Fatal("ожидалась необходимость обновления при расхождении в spec")
+	}
+}
+
+func TestBuildMergePatch_IncludesSpecChange(t *testing.T) {
+	live := newTestApplication()
+	if err := setLastAppliedAnnotation(live); err != nil {
+		t.Fatalf("не удалось проставить аннотацию last-applied: %v", err)
+	}
+
+	desired := newTestApplication()
+	desired.Spec.Project = "tenant-x"
+
+	patch, err := buildMergePatch(live, desired)
+	if err != nil {
+		t.Fatalf("buildMergePatch вернул ошибку: %v", err)
+	}
+
+	if len(patch) == 0 {
+		t.Fatal("ожидался непустой патч при расхождении в spec")
+	}
+}
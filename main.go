@@ -0,0 +1,156 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"github.ru/wbe7/argocdsyncer/config"
+	"github.ru/wbe7/argocdsyncer/controllers"
+	appmetrics "github.ru/wbe7/argocdsyncer/metrics"
+	"github.ru/wbe7/argocdsyncer/transform"
+	"github.ru/wbe7/argocdsyncer/webhooks"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appv1.AddToScheme(scheme)
+}
+
+func main() {
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	mgrOptions := ctrl.Options{
+		Scheme:                  scheme,
+		CertDir:                 config.EnvConfig.WebhookCertDir,
+		MetricsBindAddress:      config.EnvConfig.MetricsAddr,
+		HealthProbeBindAddress:  config.EnvConfig.ProbeAddr,
+		LeaderElection:          config.EnvConfig.LeaderElect,
+		LeaderElectionNamespace: config.EnvConfig.LeaderElectionNamespace,
+		LeaderElectionID:        config.EnvConfig.LeaderElectionID,
+		LeaseDuration:           &config.EnvConfig.LeaseDuration,
+		RenewDeadline:           &config.EnvConfig.RenewDeadline,
+		RetryPeriod:             &config.EnvConfig.RetryPeriod,
+		GracefulShutdownTimeout: &config.EnvConfig.ShutdownGracePeriod,
+	}
+
+	// Если задан список наблюдаемых namespace, ограничиваем кэш менеджера ими,
+	// чтобы не реагировать на Application в остальных namespace кластера.
+	if namespaces := config.EnvConfig.WatchNamespaces; len(namespaces) > 0 {
+		mgrOptions.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), mgrOptions)
+	if err != nil {
+		logrus.Fatalf("Не удалось создать manager: %v", err)
+	}
+
+	reconciler := &controllers.ApplicationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if path := config.EnvConfig.TransformConfigPath; path != "" {
+		stages, err := transform.LoadStagesFromFile(path)
+		if err != nil {
+			logrus.Fatalf("Не удалось загрузить конфигурацию трансформаций: %v", err)
+		}
+
+		pipeline, err := transform.BuildPipeline(context.Background(), mgr.GetAPIReader(), stages)
+		if err != nil {
+			logrus.Fatalf("Не удалось собрать конвейер трансформаций: %v", err)
+		}
+
+		reconciler.Transformers = pipeline
+	}
+
+	if err = reconciler.SetupWithManager(mgr); err != nil {
+		logrus.Fatalf("Не удалось создать контроллер ApplicationReconciler: %v", err)
+	}
+
+	if err = webhooks.SetupWebhooksWithManager(mgr); err != nil {
+		logrus.Fatalf("Не удалось зарегистрировать вебхуки Application: %v", err)
+	}
+
+	if err = mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		logrus.Fatalf("Не удалось добавить healthz-проверку: %v", err)
+	}
+	if err = mgr.AddReadyzCheck("readyz", applicationListCheck(mgr)); err != nil {
+		logrus.Fatalf("Не удалось добавить readyz-проверку: %v", err)
+	}
+
+	if err = mgr.Add(&appmetrics.DriftChecker{
+		Client:           mgr.GetClient(),
+		ApplicationNS:    config.EnvConfig.ApplicationNamespace,
+		DefaultFinalizer: "argoproj.io/finalizer",
+	}); err != nil {
+		logrus.Fatalf("Не удалось зарегистрировать DriftChecker: %v", err)
+	}
+
+	logrus.Info("Запуск manager")
+	if err := mgr.Start(setupSignalHandler()); err != nil {
+		logrus.Fatalf("Ошибка при работе manager: %v", err)
+	}
+}
+
+// setupSignalHandler возвращает контекст, который отменяется немедленно по
+// первому SIGTERM/SIGINT — это останавливает приём новой работы (leader
+// election, реконсиляции), а бюджет на завершение уже выполняющихся
+// реконсиляций задаётся через ctrl.Options.GracefulShutdownTimeout. Повторный
+// сигнал завершает процесс без ожидания.
+func setupSignalHandler() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		logrus.Info("Получен сигнал завершения, останавливаю приём новой работы")
+		cancel()
+
+		<-sigCh
+		logrus.Warn("Получен повторный сигнал завершения, немедленная остановка")
+		os.Exit(1)
+	}()
+
+	return ctx
+}
+
+// applicationListCheck возвращает readyz-проверку, убеждающуюся, что
+// контроллер способен получать список Application в appNamespace.
+func applicationListCheck(mgr ctrl.Manager) healthz.Checker {
+	return func(_ *http.Request) error {
+		var list appv1.ApplicationList
+		return mgr.GetClient().List(context.Background(), &list, client.InNamespace(config.EnvConfig.ApplicationNamespace))
+	}
+}
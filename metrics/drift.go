@@ -0,0 +1,91 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DriftChecker периодически пересчитывает метрику FinalizerDrift. Реализует
+// manager.Runnable, поэтому запускается и останавливается вместе с manager.
+type DriftChecker struct {
+	Client           client.Client
+	ApplicationNS    string
+	DefaultFinalizer string
+	Interval         time.Duration
+}
+
+func (d *DriftChecker) Start(ctx context.Context) error {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := d.recompute(ctx); err != nil {
+				logrus.Warnf("Не удалось пересчитать метрику дрейфа финализаторов: %v", err)
+			}
+		}
+	}
+}
+
+func (d *DriftChecker) recompute(ctx context.Context) error {
+	var sources appv1.ApplicationList
+	if err := d.Client.List(ctx, &sources); err != nil {
+		return err
+	}
+
+	var drifted float64
+	for i := range sources.Items {
+		source := &sources.Items[i]
+		if source.Namespace == d.ApplicationNS || !containsFinalizer(source.Finalizers, d.DefaultFinalizer) {
+			continue
+		}
+
+		var target appv1.Application
+		err := d.Client.Get(ctx, types.NamespacedName{Name: source.Name, Namespace: d.ApplicationNS}, &target)
+		if err != nil && kerrors.IsNotFound(err) {
+			drifted++
+		}
+	}
+
+	FinalizerDrift.Set(drifted)
+	return nil
+}
+
+func containsFinalizer(finalizers []string, target string) bool {
+	for _, f := range finalizers {
+		if f == target {
+			return true
+		}
+	}
+	return false
+}
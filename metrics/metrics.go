@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics содержит Prometheus-метрики ApplicationReconciler,
+// регистрируемые в стандартном реестре controller-runtime.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// ApplicationsSynced считает успешные завершения цикла реконсиляции.
+	ApplicationsSynced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "argocdsyncer_applications_synced_total",
+		Help: "Количество успешно завершённых реконсиляций Application.",
+	}, []string{"namespace"})
+
+	// ApplicationsCreated считает созданные целевые Application.
+	ApplicationsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "argocdsyncer_applications_created_total",
+		Help: "Количество созданных целевых Application.",
+	}, []string{"namespace"})
+
+	// ApplicationsUpdated считает обновлённые целевые Application.
+	ApplicationsUpdated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "argocdsyncer_applications_updated_total",
+		Help: "Количество обновлённых целевых Application.",
+	}, []string{"namespace"})
+
+	// ApplicationsDeleted считает удалённые целевые Application.
+	ApplicationsDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "argocdsyncer_applications_deleted_total",
+		Help: "Количество удалённых целевых Application.",
+	}, []string{"namespace"})
+
+	// ApplicationsFailed считает реконсиляции, завершившиеся ошибкой.
+	ApplicationsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "argocdsyncer_applications_failed_total",
+		Help: "Количество реконсиляций Application, завершившихся ошибкой.",
+	}, []string{"namespace", "stage"})
+
+	// ReconcileDuration измеряет длительность цикла реконсиляции по namespace.
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "argocdsyncer_reconcile_duration_seconds",
+		Help:    "Длительность цикла реконсиляции Application по namespace.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"namespace"})
+
+	// FinalizerDrift — количество исходных ресурсов с defaultFinalizer, для
+	// которых в целевом namespace отсутствует соответствующий Application.
+	FinalizerDrift = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "argocdsyncer_finalizer_drift",
+		Help: "Количество ресурсов с финализатором, для которых отсутствует целевой Application.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ApplicationsSynced,
+		ApplicationsCreated,
+		ApplicationsUpdated,
+		ApplicationsDeleted,
+		ApplicationsFailed,
+		ReconcileDuration,
+		FinalizerDrift,
+	)
+}
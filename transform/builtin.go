@@ -0,0 +1,230 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"context"
+	"strings"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Factory строит Transformer из параметров конкретного шага конвейера. ctx и r
+// передаются факториям, которым требуется обращение к API-серверу (например,
+// чтение ConfigMap); факториям, которым это не требуется, они не нужны. r —
+// некэширующий client.Reader (mgr.GetAPIReader()), пригодный для использования
+// до запуска manager, когда информеры ещё не синхронизированы.
+type Factory func(ctx context.Context, r client.Reader, params map[string]interface{}) (Transformer, error)
+
+// registry сопоставляет тип шага из YAML-конфига его Factory.
+var registry = map[string]Factory{
+	"namePrefixSuffix":      newNamePrefixSuffixTransformer,
+	"projectRemap":          newProjectRemapTransformer,
+	"projectRemapConfigMap": newProjectRemapConfigMapTransformer,
+	"destinationOverride":   newDestinationOverrideTransformer,
+	"labelPropagation":      newLabelPropagationTransformer,
+	"paramInjection":        newParamInjectionTransformer,
+}
+
+func stringParam(params map[string]interface{}, key string) string {
+	value, _ := params[key].(string)
+	return value
+}
+
+func stringSliceParam(params map[string]interface{}, key string) []string {
+	raw, ok := params[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func stringMapParam(params map[string]interface{}, key string) map[string]string {
+	raw, ok := params[key].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			result[k] = s
+		}
+	}
+	return result
+}
+
+// namePrefixSuffixTransformer добавляет префикс/суффикс к имени целевого
+// Application. Подстрока "{namespace}" в prefix/suffix подставляется
+// namespace'ом источника, что позволяет разносить одноимённые Application из
+// разных tenant-namespace.
+type namePrefixSuffixTransformer struct {
+	prefix string
+	suffix string
+}
+
+func newNamePrefixSuffixTransformer(_ context.Context, _ client.Reader, params map[string]interface{}) (Transformer, error) {
+	return &namePrefixSuffixTransformer{
+		prefix: stringParam(params, "prefix"),
+		suffix: stringParam(params, "suffix"),
+	}, nil
+}
+
+func (t *namePrefixSuffixTransformer) Transform(src *appv1.Application) (*appv1.Application, error) {
+	out := src.DeepCopy()
+	prefix := strings.ReplaceAll(t.prefix, "{namespace}", src.Namespace)
+	suffix := strings.ReplaceAll(t.suffix, "{namespace}", src.Namespace)
+	out.Name = prefix + src.Name + suffix
+	return out, nil
+}
+
+// projectRemapTransformer подставляет Spec.Project согласно карте переноса
+// namespace источника -> имя проекта Argo CD.
+type projectRemapTransformer struct {
+	mapping map[string]string
+}
+
+func newProjectRemapTransformer(_ context.Context, _ client.Reader, params map[string]interface{}) (Transformer, error) {
+	return &projectRemapTransformer{mapping: stringMapParam(params, "mapping")}, nil
+}
+
+func (t *projectRemapTransformer) Transform(src *appv1.Application) (*appv1.Application, error) {
+	out := src.DeepCopy()
+	if project, ok := t.mapping[src.Namespace]; ok {
+		out.Spec.Project = project
+	}
+	return out, nil
+}
+
+// destinationOverrideTransformer переопределяет целевой кластер Application.
+type destinationOverrideTransformer struct {
+	name   string
+	server string
+}
+
+func newDestinationOverrideTransformer(_ context.Context, _ client.Reader, params map[string]interface{}) (Transformer, error) {
+	return &destinationOverrideTransformer{
+		name:   stringParam(params, "name"),
+		server: stringParam(params, "server"),
+	}, nil
+}
+
+func (t *destinationOverrideTransformer) Transform(src *appv1.Application) (*appv1.Application, error) {
+	out := src.DeepCopy()
+	if t.name != "" {
+		out.Spec.Destination.Name = t.name
+	}
+	if t.server != "" {
+		out.Spec.Destination.Server = t.server
+	}
+	return out, nil
+}
+
+// labelPropagationTransformer переносит метки источника на целевой Application
+// с учётом allow/deny списков. Пустой allow означает отсутствие ограничения.
+type labelPropagationTransformer struct {
+	allow []string
+	deny  []string
+}
+
+func newLabelPropagationTransformer(_ context.Context, _ client.Reader, params map[string]interface{}) (Transformer, error) {
+	return &labelPropagationTransformer{
+		allow: stringSliceParam(params, "allow"),
+		deny:  stringSliceParam(params, "deny"),
+	}, nil
+}
+
+func (t *labelPropagationTransformer) Transform(src *appv1.Application) (*appv1.Application, error) {
+	out := src.DeepCopy()
+
+	out.Labels = map[string]string{}
+	for key, value := range src.Labels {
+		if t.denied(key) || !t.allowed(key) {
+			continue
+		}
+		out.Labels[key] = value
+	}
+
+	return out, nil
+}
+
+func (t *labelPropagationTransformer) allowed(key string) bool {
+	if len(t.allow) == 0 {
+		return true
+	}
+	for _, allowed := range t.allow {
+		if allowed == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *labelPropagationTransformer) denied(key string) bool {
+	for _, denied := range t.deny {
+		if denied == key {
+			return true
+		}
+	}
+	return false
+}
+
+// paramInjectionTransformer добавляет Kustomize-образы и Helm-параметры к
+// Spec.Source целевого Application.
+type paramInjectionTransformer struct {
+	kustomizeImages []string
+	helmParameters  map[string]string
+}
+
+func newParamInjectionTransformer(_ context.Context, _ client.Reader, params map[string]interface{}) (Transformer, error) {
+	return &paramInjectionTransformer{
+		kustomizeImages: stringSliceParam(params, "kustomizeImages"),
+		helmParameters:  stringMapParam(params, "helmParameters"),
+	}, nil
+}
+
+func (t *paramInjectionTransformer) Transform(src *appv1.Application) (*appv1.Application, error) {
+	out := src.DeepCopy()
+
+	if len(t.kustomizeImages) > 0 {
+		if out.Spec.Source.Kustomize == nil {
+			out.Spec.Source.Kustomize = &appv1.ApplicationSourceKustomize{}
+		}
+		for _, image := range t.kustomizeImages {
+			out.Spec.Source.Kustomize.Images = append(out.Spec.Source.Kustomize.Images, appv1.KustomizeImage(image))
+		}
+	}
+
+	if len(t.helmParameters) > 0 {
+		if out.Spec.Source.Helm == nil {
+			out.Spec.Source.Helm = &appv1.ApplicationSourceHelm{}
+		}
+		for name, value := range t.helmParameters {
+			out.Spec.Source.Helm.Parameters = append(out.Spec.Source.Helm.Parameters, appv1.HelmParameter{Name: name, Value: value})
+		}
+	}
+
+	return out, nil
+}
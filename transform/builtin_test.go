@@ -0,0 +1,137 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"testing"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestSource() *appv1.Application {
+	return &appv1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: "team-a",
+			Labels:    map[string]string{"team": "a", "tier": "prod"},
+		},
+	}
+}
+
+func TestNamePrefixSuffixTransformer(t *testing.T) {
+	transformer := &namePrefixSuffixTransformer{prefix: "p-", suffix: "-{namespace}"}
+
+	out, err := transformer.Transform(newTestSource())
+	if err != nil {
+		t.Fatalf("Transform вернул ошибку: %v", err)
+	}
+
+	if want := "p-app-team-a"; out.Name != want {
+		t.Fatalf("ожидалось имя %q, получено %q", want, out.Name)
+	}
+}
+
+func TestProjectRemapTransformer(t *testing.T) {
+	transformer := &projectRemapTransformer{mapping: map[string]string{"team-a": "tenant-x"}}
+
+	out, err := transformer.Transform(newTestSource())
+	if err != nil {
+		t.Fatalf("Transform вернул ошибку: %v", err)
+	}
+
+	if want := "tenant-x"; out.Spec.Project != want {
+		t.Fatalf("ожидался проект %q, получен %q", want, out.Spec.Project)
+	}
+}
+
+func TestProjectRemapTransformer_NoMatch(t *testing.T) {
+	transformer := &projectRemapTransformer{mapping: map[string]string{"team-b": "tenant-y"}}
+
+	out, err := transformer.Transform(newTestSource())
+	if err != nil {
+		t.Fatalf("Transform вернул ошибку: %v", err)
+	}
+
+	if out.Spec.Project != "" {
+		t.Fatalf("ожидалось отсутствие изменений при несовпадении namespace, получен проект %q", out.Spec.Project)
+	}
+}
+
+func TestDestinationOverrideTransformer(t *testing.T) {
+	transformer := &destinationOverrideTransformer{name: "prod-cluster"}
+
+	out, err := transformer.Transform(newTestSource())
+	if err != nil {
+		t.Fatalf("Transform вернул ошибку: %v", err)
+	}
+
+	if want := "prod-cluster"; out.Spec.Destination.Name != want {
+		t.Fatalf("ожидался destination.name %q, получен %q", want, out.Spec.Destination.Name)
+	}
+}
+
+func TestLabelPropagationTransformer_AllowList(t *testing.T) {
+	transformer := &labelPropagationTransformer{allow: []string{"team"}}
+
+	out, err := transformer.Transform(newTestSource())
+	if err != nil {
+		t.Fatalf("Transform вернул ошибку: %v", err)
+	}
+
+	if _, ok := out.Labels["tier"]; ok {
+		t.Fatal("метка tier не входит в allow-лист и не должна была перенестись")
+	}
+	if out.Labels["team"] != "a" {
+		t.Fatalf("метка team должна была перенестись, получено %q", out.Labels["team"])
+	}
+}
+
+func TestLabelPropagationTransformer_DenyList(t *testing.T) {
+	transformer := &labelPropagationTransformer{deny: []string{"tier"}}
+
+	out, err := transformer.Transform(newTestSource())
+	if err != nil {
+		t.Fatalf("Transform вернул ошибку: %v", err)
+	}
+
+	if _, ok := out.Labels["tier"]; ok {
+		t.Fatal("метка tier входит в deny-лист и не должна была перенестись")
+	}
+	if out.Labels["team"] != "a" {
+		t.Fatalf("метка team должна была перенестись, получено %q", out.Labels["team"])
+	}
+}
+
+func TestParamInjectionTransformer(t *testing.T) {
+	transformer := &paramInjectionTransformer{
+		kustomizeImages: []string{"repo/image:v2"},
+		helmParameters:  map[string]string{"replicaCount": "3"},
+	}
+
+	out, err := transformer.Transform(newTestSource())
+	if err != nil {
+		t.Fatalf("Transform вернул ошибку: %v", err)
+	}
+
+	if len(out.Spec.Source.Kustomize.Images) != 1 || string(out.Spec.Source.Kustomize.Images[0]) != "repo/image:v2" {
+		t.Fatalf("ожидался один kustomize-образ repo/image:v2, получено %v", out.Spec.Source.Kustomize.Images)
+	}
+	if len(out.Spec.Source.Helm.Parameters) != 1 || out.Spec.Source.Helm.Parameters[0].Value != "3" {
+		t.Fatalf("ожидался один helm-параметр replicaCount=3, получено %v", out.Spec.Source.Helm.Parameters)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newProjectRemapConfigMapTransformer — Factory для шага "projectRemapConfigMap".
+// Параметры name/namespace задают ConfigMap, из Data которой читается карта
+// "namespace источника -> проект Argo CD".
+func newProjectRemapConfigMapTransformer(ctx context.Context, r client.Reader, params map[string]interface{}) (Transformer, error) {
+	name := stringParam(params, "name")
+	namespace := stringParam(params, "namespace")
+	if name == "" || namespace == "" {
+		return nil, fmt.Errorf("шагу projectRemapConfigMap требуются параметры name и namespace")
+	}
+
+	return NewProjectRemapFromConfigMap(ctx, r, name, namespace)
+}
+
+// NewProjectRemapFromConfigMap строит projectRemapTransformer, читая карту
+// "namespace источника -> проект Argo CD" из Data ConfigMap name/namespace. r
+// должен быть некэширующим читателем, пригодным для вызова до запуска manager.
+func NewProjectRemapFromConfigMap(ctx context.Context, r client.Reader, name, namespace string) (Transformer, error) {
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &cm); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать ConfigMap %v/%v с картой проектов: %w", namespace, name, err)
+	}
+
+	mapping := make(map[string]string, len(cm.Data))
+	for k, v := range cm.Data {
+		mapping[k] = v
+	}
+
+	return &projectRemapTransformer{mapping: mapping}, nil
+}
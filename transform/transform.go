@@ -0,0 +1,101 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package transform предоставляет конвейер трансформаций, из которого
+// ApplicationReconciler выводит целевой Application на основе исходного.
+package transform
+
+import (
+	"context"
+	"fmt"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Transformer — один шаг конвейера. Получает на вход результат предыдущего
+// шага (для первого шага — базовую копию целевого Application, выведенную из
+// источника и сохраняющую его namespace) и возвращает следующее состояние.
+type Transformer interface {
+	Transform(src *appv1.Application) (*appv1.Application, error)
+}
+
+// Pipeline — упорядоченная цепочка Transformer.
+type Pipeline []Transformer
+
+// Run последовательно прогоняет src через все шаги конвейера.
+func (p Pipeline) Run(src *appv1.Application) (*appv1.Application, error) {
+	current := src
+	for _, t := range p {
+		next, err := t.Transform(current)
+		if err != nil {
+			return nil, err
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// StageConfig описывает один шаг конвейера, загружаемый из YAML:
+//
+//	transformers:
+//	  - type: namePrefixSuffix
+//	    params:
+//	      suffix: "-{namespace}"
+type StageConfig struct {
+	Type   string                 `mapstructure:"type"`
+	Params map[string]interface{} `mapstructure:"params"`
+}
+
+// LoadStagesFromFile читает список StageConfig из YAML/JSON/TOML-файла через viper.
+func LoadStagesFromFile(path string) ([]StageConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать конфигурацию трансформаций %v: %w", path, err)
+	}
+
+	var stages []StageConfig
+	if err := v.UnmarshalKey("transformers", &stages); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать конфигурацию трансформаций %v: %w", path, err)
+	}
+
+	return stages, nil
+}
+
+// BuildPipeline строит Pipeline из списка StageConfig в заданном порядке.
+// ctx и r передаются факториям шагов, которым требуется обращение к
+// API-серверу (например, projectRemapConfigMap); остальным они не нужны. r
+// должен быть некэширующим (mgr.GetAPIReader()), так как BuildPipeline
+// вызывается в main() до запуска manager, когда кэш ещё не синхронизирован.
+func BuildPipeline(ctx context.Context, r client.Reader, stages []StageConfig) (Pipeline, error) {
+	pipeline := make(Pipeline, 0, len(stages))
+	for _, stage := range stages {
+		factory, ok := registry[stage.Type]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный тип трансформера %q", stage.Type)
+		}
+
+		transformer, err := factory(ctx, r, stage.Params)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось создать трансформер %q: %w", stage.Type, err)
+		}
+
+		pipeline = append(pipeline, transformer)
+	}
+	return pipeline, nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuildPipeline_RunsStagesInOrder(t *testing.T) {
+	stages := []StageConfig{
+		{Type: "namePrefixSuffix", Params: map[string]interface{}{"prefix": "p-"}},
+		{Type: "projectRemap", Params: map[string]interface{}{"mapping": map[string]interface{}{"team-a": "tenant-x"}}},
+	}
+
+	pipeline, err := BuildPipeline(context.Background(), nil, stages)
+	if err != nil {
+		t.Fatalf("BuildPipeline вернул ошибку: %v", err)
+	}
+
+	out, err := pipeline.Run(newTestSource())
+	if err != nil {
+		t.Fatalf("Run вернул ошибку: %v", err)
+	}
+
+	if want := "p-app"; out.Name != want {
+		t.Fatalf("ожидалось имя %q, получено %q", want, out.Name)
+	}
+	if want := "tenant-x"; out.Spec.Project != want {
+		t.Fatalf("ожидался проект %q, получен %q", want, out.Spec.Project)
+	}
+}
+
+func TestBuildPipeline_UnknownStageType(t *testing.T) {
+	stages := []StageConfig{{Type: "doesNotExist"}}
+
+	if _, err := BuildPipeline(context.Background(), nil, stages); err == nil {
+		t.Fatal("ожидалась ошибка для неизвестного типа трансформера")
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhooks содержит admission-вебхуки для ресурсов argoproj.io/Application,
+// выносящие валидацию и дефолтинг из цикла реконсиляции ApplicationReconciler.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.ru/wbe7/argocdsyncer/config"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// ValidatingWebhookPath — путь валидирующего вебхука Application.
+	ValidatingWebhookPath = "/validate-argoproj-io-v1alpha1-application"
+	// MutatingWebhookPath — путь дефолтинг-вебхука Application.
+	MutatingWebhookPath = "/mutate-argoproj-io-v1alpha1-application"
+
+	defaultFinalizer = "argoproj.io/finalizer"
+	managedByLabel   = "app.kubernetes.io/managed-by"
+	managedByValue   = "argocdsyncer"
+)
+
+// SetupWebhooksWithManager регистрирует валидирующий и дефолтинг вебхуки
+// Application на встроенном webhook-сервере manager.
+func SetupWebhooksWithManager(mgr ctrl.Manager) error {
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("не удалось создать decoder для вебхуков Application: %w", err)
+	}
+
+	server := mgr.GetWebhookServer()
+	server.Register(ValidatingWebhookPath, &webhook.Admission{Handler: &ApplicationValidator{decoder: decoder}})
+	server.Register(MutatingWebhookPath, &webhook.Admission{Handler: &ApplicationDefaulter{decoder: decoder}})
+
+	return nil
+}
+
+// ApplicationValidator реализует admission.Handler и не допускает в кластер
+// Application, нарушающие политики (namespace назначения, allow-листы,
+// обязательные метки).
+type ApplicationValidator struct {
+	decoder *admission.Decoder
+}
+
+func (v *ApplicationValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	var app appv1.Application
+	if err := v.decoder.Decode(req, &app); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if err := validateApplication(&app); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	return admission.Allowed("")
+}
+
+func validateApplication(app *appv1.Application) error {
+	if app.Spec.Destination.Namespace != app.Namespace {
+		return fmt.Errorf(
+			"namespace назначения %q должен совпадать с namespace ресурса %q",
+			app.Spec.Destination.Namespace, app.Namespace,
+		)
+	}
+
+	if !isAllowed(app.Spec.Source.RepoURL, config.EnvConfig.AllowedRepoURLs) {
+		return fmt.Errorf("repoURL %q не входит в список разрешённых", app.Spec.Source.RepoURL)
+	}
+
+	if !isAllowed(app.Spec.Project, config.EnvConfig.AllowedProjects) {
+		return fmt.Errorf("проект %q не входит в список разрешённых", app.Spec.Project)
+	}
+
+	destination := app.Spec.Destination.Name
+	if destination == "" {
+		destination = app.Spec.Destination.Server
+	}
+	if !isAllowed(destination, config.EnvConfig.AllowedDestinationClusters) {
+		return fmt.Errorf("целевой кластер %q не входит в список разрешённых", destination)
+	}
+
+	for _, label := range config.EnvConfig.RequiredLabels {
+		if _, ok := app.Labels[label]; !ok {
+			return fmt.Errorf("отсутствует обязательная метка %q", label)
+		}
+	}
+
+	return nil
+}
+
+// isAllowed возвращает true, если allowList пуст (ограничение не задано)
+// либо содержит value.
+func isAllowed(value string, allowList []string) bool {
+	if len(allowList) == 0 {
+		return true
+	}
+	for _, allowed := range allowList {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplicationDefaulter реализует admission.Handler и подставляет финализатор,
+// стандартные метки и проект по умолчанию перед допуском Application в кластер.
+type ApplicationDefaulter struct {
+	decoder *admission.Decoder
+}
+
+func (d *ApplicationDefaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	var app appv1.Application
+	if err := d.decoder.Decode(req, &app); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	defaultApplication(&app)
+
+	marshaled, err := json.Marshal(app)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+func defaultApplication(app *appv1.Application) {
+	controllerutil.AddFinalizer(app, defaultFinalizer)
+
+	if app.Spec.Project == "" {
+		app.Spec.Project = config.EnvConfig.DefaultProject
+	}
+
+	if app.Labels == nil {
+		app.Labels = map[string]string{}
+	}
+	app.Labels[managedByLabel] = managedByValue
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.ru/wbe7/argocdsyncer/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newAdmissionRequest(app *appv1.Application) admission.Request {
+	raw, err := json.Marshal(app)
+	Expect(err).NotTo(HaveOccurred())
+
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+var _ = Describe("ApplicationValidator", func() {
+	decoder, err := admission.NewDecoder(scheme)
+	Expect(err).NotTo(HaveOccurred())
+	validator := &ApplicationValidator{decoder: decoder}
+
+	It("допускает Application с совпадающим namespace назначения", func() {
+		app := &appv1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+			Spec:       appv1.ApplicationSpec{Destination: appv1.ApplicationDestination{Namespace: "team-a"}},
+		}
+
+		resp := validator.Handle(context.Background(), newAdmissionRequest(app))
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
+	It("отклоняет Application с несовпадающим namespace назначения", func() {
+		app := &appv1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+			Spec:       appv1.ApplicationSpec{Destination: appv1.ApplicationDestination{Namespace: "team-b"}},
+		}
+
+		resp := validator.Handle(context.Background(), newAdmissionRequest(app))
+		Expect(resp.Allowed).To(BeFalse())
+	})
+
+	It("отклоняет Application с отсутствующей обязательной меткой", func() {
+		config.EnvConfig.RequiredLabels = []string{"tenant"}
+		defer func() { config.EnvConfig.RequiredLabels = nil }()
+
+		app := &appv1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+			Spec:       appv1.ApplicationSpec{Destination: appv1.ApplicationDestination{Namespace: "team-a"}},
+		}
+
+		resp := validator.Handle(context.Background(), newAdmissionRequest(app))
+		Expect(resp.Allowed).To(BeFalse())
+	})
+})
+
+var _ = Describe("ApplicationDefaulter", func() {
+	It("подставляет финализатор и проект по умолчанию", func() {
+		app := &appv1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+		}
+
+		defaultApplication(app)
+
+		Expect(app.Finalizers).To(ContainElement(defaultFinalizer))
+		Expect(app.Spec.Project).To(Equal(config.EnvConfig.DefaultProject))
+		Expect(app.Labels[managedByLabel]).To(Equal(managedByValue))
+	})
+
+	It("не перезаписывает уже заданный проект", func() {
+		app := &appv1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+			Spec:       appv1.ApplicationSpec{Project: "tenant-x"},
+		}
+
+		defaultApplication(app)
+
+		Expect(app.Spec.Project).To(Equal("tenant-x"))
+	})
+})
@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var (
+	testEnv *envtest.Environment
+	scheme  = runtime.NewScheme()
+)
+
+func TestWebhooks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhooks Suite")
+}
+
+var _ = BeforeSuite(func() {
+	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+	Expect(appv1.AddToScheme(scheme)).To(Succeed())
+
+	if !envtestAssetsAvailable() {
+		// Спеки этого сьюта вызывают Handle()/defaultApplication() напрямую и не
+		// требуют envtest для прохождения, поэтому без assets они выполняются без
+		// control plane. Реальный admission round-trip (регистрация вебхука, загрузка
+		// сертификата, HTTP-запрос от API-сервера) сьют не проверяет.
+		_, _ = GinkgoWriter.Write([]byte("KUBEBUILDER_ASSETS не настроен, envtest control plane не запускается\n"))
+		return
+	}
+
+	testEnv = &envtest.Environment{}
+
+	_, err := testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+}, 60)
+
+var _ = AfterSuite(func() {
+	if testEnv != nil {
+		Expect(testEnv.Stop()).To(Succeed())
+	}
+})
+
+// envtestAssetsAvailable проверяет, что KUBEBUILDER_ASSETS указывает на
+// директорию с бинарниками etcd и kube-apiserver, необходимыми envtest.
+func envtestAssetsAvailable() bool {
+	dir := os.Getenv("KUBEBUILDER_ASSETS")
+	if dir == "" {
+		return false
+	}
+
+	for _, bin := range []string{"etcd", "kube-apiserver"} {
+		if _, err := os.Stat(filepath.Join(dir, bin)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}